@@ -2,21 +2,33 @@
 package collector
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/fsnotify/fsnotify"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/vishvananda/netlink"
+	"gopkg.in/alecthomas/kingpin.v2"
 	"io/ioutil"
+	"net/http"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"os"
 )
 
+var sriovDPUEndpoint = kingpin.Flag("collector.sriovnet.dpu-endpoint",
+	"Address of a DPU-side agent (e.g. host:port) to query for VF counters on smartNIC/DPU PFs. "+
+		"If unset, DPU PFs report host-side counters only.").Default("").String()
+
 const (
 	sriovStatSubsystem = "sriovnet"
-	sysBusPci = "/sys/bus/pci/devices"
 	totalVfFile      = "sriov_totalvfs"
 	pfNameFile = "/net"
 	netClassFile = "/class"
@@ -24,6 +36,9 @@ const (
 	netClass = 0x020000
 )
 
+//sysBusPci is a var rather than a const so tests can point it at a fixture tree.
+var sysBusPci = "/sys/bus/pci/devices"
+
 
 func init() {
 	registerCollector("sriovnet", defaultDisabled, NewSriovNetCollector)
@@ -38,32 +53,141 @@ type sriovStats map[string]float64
 //sriovNetCollector implements the collector interface to be picked up by node exporter.
 type sriovNetCollector struct {
 	logger       log.Logger
+
+	vfCacheMu  sync.Mutex
+	vfCache    map[string]vfCacheEntry
+	watcher    *fsnotify.Watcher
+	watchedPFs map[string]bool
+}
+
+//vfCacheEntry is a cached PF->VF mapping along with the sriov_numvfs count it was discovered at,
+//so a scrape can detect a stale entry even if the sriov_numvfs inotify watch misses the write.
+type vfCacheEntry struct {
+	vfs    vfWithRoot
+	numVfs int
 }
 //sriovStatReader is an interface which takes in the physical function name and vf id and returns the stats for the VF
 type sriovStatReader interface {
-	ReadStats(vfID string, pfName string) sriovStats
+	ReadStats(pfName string, vfID string, logger log.Logger) sriovStats
+}
+
+//sriovStatReaderRegistry maps a PF driver name to the sriovStatReader able to read its VF stats.
+//Drivers register themselves here, either below or via RegisterSriovStatReader.
+var sriovStatReaderRegistry = map[string]sriovStatReader{}
+
+func init() {
+	RegisterSriovStatReader("i40e", i40eReader{})
+	RegisterSriovStatReader("mlx5_core", mlx5Reader{})
+	RegisterSriovStatReader("ice", iceReader{})
+	RegisterSriovStatReader("igb", igbReader{})
+}
+
+//RegisterSriovStatReader registers a sriovStatReader for the given PF driver name, allowing
+//support for additional NIC drivers to be added without modifying statReaderForPF.
+func RegisterSriovStatReader(driver string, reader sriovStatReader) {
+	sriovStatReaderRegistry[driver] = reader
 }
 
 //NewSriovNetCollector returns the collector required for registration with node exporter
 func NewSriovNetCollector(logger log.Logger) (Collector, error){
 	s :=  &sriovNetCollector{
-		logger: logger,
+		logger:     logger,
+		vfCache:    make(map[string]vfCacheEntry),
+		watchedPFs: make(map[string]bool),
 	}
+	s.startVFCacheWatcher()
 	return s , nil
 }
 
-//statReaderForPF returns the correct stat reader for the given PF
-//currently only i40e is implemented, but other drivers can be implemented and picked up here.
-func statReaderForPF (pf string) sriovStatReader {
-	pfDriverPath := filepath.Join(sysBusPci, pf, driverFile)
-	driverInfo, _ := os.Readlink(pfDriverPath)
-	pfDriver := filepath.Base(driverInfo)
-	switch pfDriver {
-	case "i40e":
-		return i40eReader{}
-	default:
-		return nil
+//startVFCacheWatcher starts an inotify watcher used to eagerly invalidate the cached PF->VF
+//mapping whenever a PF's sriov_numvfs file is written, i.e. VFs are (re)configured. This is a
+//best-effort fast path only: inotify on sysfs attribute files doesn't reliably deliver events
+//on every write, so cachedVFList also re-checks sriov_numvfs on every scrape as the source of
+//truth for staleness.
+func (c *sriovNetCollector) startVFCacheWatcher() {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "could not start sriov_numvfs watcher, VF cache will only be refreshed when sriov_numvfs changes between scrapes", "err", err)
+		return
+	}
+	c.watcher = w
+	go func() {
+		for event := range w.Events {
+			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				c.invalidateVFCache(filepath.Base(filepath.Dir(event.Name)))
+			}
+		}
+	}()
+}
+
+//watchPF starts watching a PF's sriov_numvfs file for changes, if it isn't already watched.
+func (c *sriovNetCollector) watchPF(pciAddr string) {
+	if c.watcher == nil {
+		return
+	}
+	c.vfCacheMu.Lock()
+	defer c.vfCacheMu.Unlock()
+	if c.watchedPFs[pciAddr] {
+		return
 	}
+	numVfsPath := filepath.Join(sysBusPci, pciAddr, "sriov_numvfs")
+	if err := c.watcher.Add(numVfsPath); err == nil {
+		c.watchedPFs[pciAddr] = true
+	}
+}
+
+//invalidateVFCache drops the cached VF list for a PF so it is rediscovered on the next scrape.
+func (c *sriovNetCollector) invalidateVFCache(pciAddr string) {
+	c.vfCacheMu.Lock()
+	defer c.vfCacheMu.Unlock()
+	delete(c.vfCache, pciAddr)
+}
+
+//cachedVFList returns the VF list for a PF, discovering and caching it on first use. The cache
+//is keyed not just on PF but validated against the PF's current sriov_numvfs count on every
+//call, so a missed inotify event can't leave a stale VF list in place indefinitely.
+func (c *sriovNetCollector) cachedVFList(pciAddr string) (vfWithRoot, error) {
+	currentNumVfs := readSysfsInt(filepath.Join(sysBusPci, pciAddr, "sriov_numvfs"))
+
+	c.vfCacheMu.Lock()
+	if entry, ok := c.vfCache[pciAddr]; ok && entry.numVfs == currentNumVfs {
+		c.vfCacheMu.Unlock()
+		return entry.vfs, nil
+	}
+	c.vfCacheMu.Unlock()
+
+	vfs, err := vfList(pciAddr)
+	if err != nil {
+		return nil, err
+	}
+	c.watchPF(pciAddr)
+	c.vfCacheMu.Lock()
+	c.vfCache[pciAddr] = vfCacheEntry{vfs: vfs, numVfs: currentNumVfs}
+	c.vfCacheMu.Unlock()
+	return vfs, nil
+}
+
+//statReaderForPF returns the correct stat reader for the given PF. PFs in switchdev eswitch
+//mode always get switchdevReader, since the driver's own stat reader no longer applies once VF
+//counters move to the representor netdevs; otherwise the reader is looked up from
+//sriovStatReaderRegistry by driver name. Additional drivers can be supported by calling
+//RegisterSriovStatReader without modifying this function. If the PF belongs to a known DPU/smartNIC
+//and --collector.sriovnet.dpu-endpoint is set, the reader is wrapped with dpuReader so VF counters
+//are merged in from the DPU's own agent.
+func statReaderForPF (pf string, logger log.Logger) sriovStatReader {
+	var reader sriovStatReader
+	if pfName := getPFName(pf); pfName != "" && isSwitchdevMode(pfName) {
+		reader = switchdevReader{}
+	} else {
+		pfDriverPath := filepath.Join(sysBusPci, pf, driverFile)
+		driverInfo, _ := os.Readlink(pfDriverPath)
+		pfDriver := filepath.Base(driverInfo)
+		reader = sriovStatReaderRegistry[pfDriver]
+	}
+	if isDPUPF(pf) && *sriovDPUEndpoint != "" {
+		return dpuReader{hostReader: reader, endpoint: *sriovDPUEndpoint, logger: logger}
+	}
+	return reader
 }
 // Update looks for all SRIOV Network PFs on the system, looks for the VFs for each, and reports per VF stats.
 func (c *sriovNetCollector) Update(ch chan<- prometheus.Metric) error {
@@ -72,17 +196,21 @@ func (c *sriovNetCollector) Update(ch chan<- prometheus.Metric) error {
 		return err
 	}
 	for _, pf := range pfList {
-		reader := statReaderForPF(pf)
+		pfName := getPFName(pf)
+		c.updatePFInfo(ch, pf, pfName)
+
+		reader := statReaderForPF(pf, c.logger)
 		if reader == nil {
 			continue
 		}
-		vfs, err  := vfList(pf)
+		vfs, err  := c.cachedVFList(pf)
 		if err != nil{
 			continue
 		}
-		pfName := getPFName(pf)
+		// Fetched once per PF rather than once per VF: it carries the full Vfs slice already.
+		pfLink, _ := netlink.LinkByName(pfName)
 		for id, address := range vfs {
-			stats := reader.ReadStats(pfName,id)
+			stats := reader.ReadStats(pfName, id, c.logger)
 			for name, v := range stats {
 				desc := prometheus.NewDesc(
 					prometheus.BuildFQName(namespace, sriovStatSubsystem, name),
@@ -98,13 +226,221 @@ func (c *sriovNetCollector) Update(ch chan<- prometheus.Metric) error {
 					address,
 				)
 			}
+
+			representor := ""
+			if isSwitchdevReader(reader) {
+				if rep, found := representorForVF(pfName, id); found {
+					representor = rep
+					ch <- prometheus.MustNewConstMetric(
+						prometheus.NewDesc(
+							prometheus.BuildFQName(namespace, sriovStatSubsystem, "vf_representor_info"),
+							"Constant metric with value 1 labeling the switchdev representor netdev for a VF.",
+							[]string{"pfName", "vf", "vfAddress", "representor"}, nil,
+						),
+						prometheus.GaugeValue, 1, pfName, id, address, rep,
+					)
+				}
+			}
+			c.updateVFConfig(ch, pfName, id, address, representor, pfLink)
+			c.updateVFTopology(ch, pf, pfName, id, address)
 		}
 	}
 	return nil
 }
 
-//getSriovPFs returns the SRIOV capable Physical Network functions for the host
-func (c sriovNetCollector )getSriovPFs() ([]string , error) {
+//vfConfig describes the administrative/operational state configured for a VF, as reported by
+//the PF driver rather than measured traffic counters.
+type vfConfig struct {
+	mac         string
+	vlan        int
+	qos         int
+	minTxRate   int
+	maxTxRate   int
+	spoofChk    bool
+	trust       bool
+	linkState   string
+	representor string
+}
+
+//updateVFConfig emits gauge metrics describing the configured state of a single VF. representor
+//is the switchdev representor netdev name for this VF, or "" on PFs not in switchdev mode.
+func (c *sriovNetCollector) updateVFConfig(ch chan<- prometheus.Metric, pfName string, vfID string, vfAddress string, representor string, pfLink netlink.Link) {
+	cfg, err := getVFConfig(pfName, vfID, pfLink)
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "could not read VF config", "pf", pfName, "vf", vfID, "err", err)
+		return
+	}
+	cfg.representor = representor
+
+	labels := []string{"pfName", "vf", "vfAddress"}
+	labelValues := []string{pfName, vfID, vfAddress}
+
+	infoDesc := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sriovStatSubsystem, "vf_info"),
+		"Constant metric with value 1 labeled with the administrative state of the VF.",
+		append(labels, "mac", "linkState", "representor"), nil,
+	)
+	ch <- prometheus.MustNewConstMetric(infoDesc, prometheus.GaugeValue, 1,
+		append(labelValues, cfg.mac, cfg.linkState, cfg.representor)...)
+
+	gauges := map[string]float64{
+		"vf_vlan":         float64(cfg.vlan),
+		"vf_qos":          float64(cfg.qos),
+		"vf_min_tx_rate":  float64(cfg.minTxRate),
+		"vf_max_tx_rate":  float64(cfg.maxTxRate),
+		"vf_spoofchk":     boolToFloat(cfg.spoofChk),
+		"vf_trust":        boolToFloat(cfg.trust),
+	}
+	for name, v := range gauges {
+		desc := prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, sriovStatSubsystem, name),
+			fmt.Sprintf("Configured %s.", name),
+			labels, nil,
+		)
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, labelValues...)
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+//getVFConfig returns the configured administrative state of a VF, preferring netlink
+//IFLA_VF_* attributes and falling back to sysfs for drivers that don't populate them. pfLink is
+//the PF's netlink link, fetched once per PF by the caller rather than once per VF.
+func getVFConfig(pfName string, vfID string, pfLink netlink.Link) (vfConfig, error) {
+	if cfg, err := getVFConfigNetlink(pfName, vfID, pfLink); err == nil {
+		return cfg, nil
+	}
+	return getVFConfigSysfs(pfName, vfID)
+}
+
+//getVFConfigNetlink reads the VF configuration from the PF link's IFLA_VF_* attributes.
+func getVFConfigNetlink(pfName string, vfID string, pfLink netlink.Link) (vfConfig, error) {
+	id, err := strconv.Atoi(vfID)
+	if err != nil {
+		return vfConfig{}, err
+	}
+	if pfLink == nil {
+		return vfConfig{}, fmt.Errorf("no netlink link for PF %s", pfName)
+	}
+	for _, vf := range pfLink.Attrs().Vfs {
+		if vf.ID != id {
+			continue
+		}
+		return vfConfig{
+			mac:       vf.Mac.String(),
+			vlan:      vf.Vlan,
+			qos:       vf.Qos,
+			minTxRate: int(vf.MinTxRate),
+			maxTxRate: int(vf.MaxTxRate),
+			spoofChk:  vf.Spoofchk,
+			trust:     vf.Trust != 0,
+			linkState: vfLinkStateString(vf.LinkState),
+		}, nil
+	}
+	return vfConfig{}, fmt.Errorf("no VF with id %s found on PF %s", vfID, pfName)
+}
+
+//vfLinkStateString converts the netlink VF link state constant into the string used by "ip link".
+func vfLinkStateString(state uint32) string {
+	switch state {
+	case netlink.VF_LINK_STATE_ENABLE:
+		return "enable"
+	case netlink.VF_LINK_STATE_DISABLE:
+		return "disable"
+	default:
+		return "auto"
+	}
+}
+
+//getVFConfigSysfs reads the VF configuration from sysfs, for drivers that expose it there but
+//aren't fully supported by netlink (e.g. older out-of-tree drivers).
+func getVFConfigSysfs(pfName string, vfID string) (vfConfig, error) {
+	vfRoot := fmt.Sprintf("/sys/class/net/%s/device/sriov/%s/", pfName, vfID)
+	if _, err := os.Stat(vfRoot); err != nil {
+		return vfConfig{}, err
+	}
+	cfg := vfConfig{
+		linkState: readSysfsString(vfRoot + "link_state", "auto"),
+		mac:       readSysfsString(vfRoot + "mac", ""),
+		vlan:      readSysfsInt(vfRoot + "vlan"),
+		qos:       readSysfsInt(vfRoot + "qos"),
+		minTxRate: readSysfsInt(vfRoot + "min_tx_rate"),
+		maxTxRate: readSysfsInt(vfRoot + "max_tx_rate"),
+		spoofChk:  readSysfsInt(vfRoot+"spoofchk") != 0,
+		trust:     readSysfsInt(vfRoot+"trust") != 0,
+	}
+	return cfg, nil
+}
+
+func readSysfsString(path string, fallback string) string {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fallback
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func readSysfsInt(path string) int {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+//getSriovPFs returns the SRIOV capable Physical Network functions for the host. It prefers
+//enumerating netdevs via netlink, which is a single syscall, and falls back to walking every
+//PCI device in sysfs when netlink is unavailable.
+func (c *sriovNetCollector )getSriovPFs() ([]string , error) {
+	if sriovPFs, err := c.getSriovPFsNetlink(); err == nil {
+		return sriovPFs, nil
+	}
+	return c.getSriovPFsSysfs()
+}
+
+//getSriovPFsNetlink enumerates netdevs via netlink.LinkList and filters to those backed by a
+//PCI device that exposes sriov_totalvfs. Several netdevs can resolve to the same PCI device -
+//most notably switchdev representors, whose "device" symlink points at the PF itself - so
+//results are deduplicated by PCI address before being returned.
+func (c *sriovNetCollector) getSriovPFsNetlink() ([]string, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	sriovPFs := make([]string, 0)
+	for _, link := range links {
+		pciAddr, err := pciAddressForNetdev(link.Attrs().Name)
+		if err != nil {
+			continue
+		}
+		if seen[pciAddr] {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(sysBusPci, pciAddr, totalVfFile)); err != nil {
+			continue
+		}
+		seen[pciAddr] = true
+		sriovPFs = append(sriovPFs, pciAddr)
+	}
+	if len(sriovPFs) == 0 {
+		return sriovPFs, errors.New("no sriov net devices found via netlink")
+	}
+	return sriovPFs, nil
+}
+
+//getSriovPFsSysfs returns the SRIOV capable Physical Network functions for the host by walking
+//every PCI device in sysfs. Used as a fallback when netlink discovery is unavailable.
+func (c *sriovNetCollector) getSriovPFsSysfs() ([]string , error) {
 	sriovPFs := make([]string, 0)
 	devs := getPCIDevs()
 	if len(devs) == 0 {
@@ -122,7 +458,7 @@ func (c sriovNetCollector )getSriovPFs() ([]string , error) {
 }
 
 // IsSriovPF checks if is device SRIOV capable net device. It checks if the sriov_totalvfs file exists for the given PCI address
-func (c sriovNetCollector) isSriovNetPF(pciAddr string) bool {
+func (c *sriovNetCollector) isSriovNetPF(pciAddr string) bool {
 	totalVfFilePath := filepath.Join(sysBusPci, pciAddr, totalVfFile)
 	devClassFilePath := filepath.Join(sysBusPci,pciAddr,netClassFile)
 	if !c.isNetDevice(devClassFilePath){
@@ -135,7 +471,7 @@ func (c sriovNetCollector) isSriovNetPF(pciAddr string) bool {
 }
 
 // isNetDevice checks if the device is a net device by checking its device class
-func (c sriovNetCollector) isNetDevice (filepath string) bool {
+func (c *sriovNetCollector) isNetDevice (filepath string) bool {
 
 	file, err := ioutil.ReadFile(filepath)
 	if err != nil {
@@ -158,8 +494,53 @@ func getPCIDevs () []os.FileInfo {
 	return links
 }
 
-//getVFsFromPF returns the Virtual Functions associated with a specific SRIOV Physical Function
+//vfList returns the Virtual Functions associated with a specific SRIOV Physical Function.
+//It prefers netlink, which returns the full VF list for the PF in one syscall, falling back to
+//globbing sysfs virtfn* symlinks for drivers/kernels where netlink doesn't report VFs.
 func vfList(pfAddress string) (vfWithRoot, error) {
+	if pfName := getPFName(pfAddress); pfName != "" {
+		if vfs, err := vfListNetlink(pfAddress, pfName); err == nil {
+			return vfs, nil
+		}
+	}
+	return vfListSysfs(pfAddress)
+}
+
+//vfListNetlink resolves the VFs of a PF from its netlink IFLA_VF_* attributes. The VF PCI
+//address itself still has to come from the virtfn<id> sysfs symlink, as netlink doesn't report it.
+func vfListNetlink(pfAddress string, pfName string) (vfWithRoot, error) {
+	link, err := netlink.LinkByName(pfName)
+	if err != nil {
+		return nil, err
+	}
+	netlinkVfs := link.Attrs().Vfs
+	if len(netlinkVfs) == 0 {
+		return nil, fmt.Errorf("no VFs reported via netlink for PF %s", pfName)
+	}
+	vfs := make(vfWithRoot, len(netlinkVfs))
+	for _, vf := range netlinkVfs {
+		vfID := strconv.Itoa(vf.ID)
+		vfAddr, err := vfPCIAddress(pfAddress, vfID)
+		if err != nil {
+			continue
+		}
+		vfs[vfID] = vfAddr
+	}
+	return vfs, nil
+}
+
+//vfPCIAddress resolves a single VF's PCI address by following the PF's virtfn<id> sysfs symlink.
+func vfPCIAddress(pfAddress string, vfID string) (string, error) {
+	linkName, err := filepath.EvalSymlinks(filepath.Join(sysBusPci, pfAddress, "virtfn"+vfID))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(linkName), nil
+}
+
+//vfListSysfs returns the Virtual Functions associated with a specific SRIOV Physical Function
+//by globbing its virtfn* sysfs symlinks.
+func vfListSysfs(pfAddress string) (vfWithRoot, error) {
 	vfList := make(vfWithRoot, 0)
 	pfDir := filepath.Join(sysBusPci, pfAddress)
 	_, err := os.Lstat(pfDir)
@@ -197,16 +578,132 @@ func getPFName (device string) string {
 	return pfdir[0].Name()
 }
 
-//i40eReader is able to read stats from Physical functions running the i40e driver.
-type i40eReader struct {
+//updatePFInfo emits PF-level metrics: the number of VFs supported vs. configured, the
+//operational state of the PF netdev, and static driver/topology information. The PF's firmware
+//version is not reported.
+func (c *sriovNetCollector) updatePFInfo(ch chan<- prometheus.Metric, pciAddr string, pfName string) {
+	totalVfs := readSysfsInt(filepath.Join(sysBusPci, pciAddr, totalVfFile))
+	numVfs := readSysfsInt(filepath.Join(sysBusPci, pciAddr, "sriov_numvfs"))
+
+	labels := []string{"pfName"}
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, sriovStatSubsystem, "pf_totalvfs"),
+			"Maximum number of VFs the PF supports.", labels, nil),
+		prometheus.GaugeValue, float64(totalVfs), pfName,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, sriovStatSubsystem, "pf_numvfs"),
+			"Number of VFs currently configured on the PF.", labels, nil),
+		prometheus.GaugeValue, float64(numVfs), pfName,
+	)
+
+	if mtu := readSysfsInt(filepath.Join("/sys/class/net", pfName, "mtu")); mtu != 0 {
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(prometheus.BuildFQName(namespace, sriovStatSubsystem, "pf_mtu_bytes"),
+				"MTU of the PF netdev.", labels, nil),
+			prometheus.GaugeValue, float64(mtu), pfName,
+		)
+	}
+
+	speed := -1
+	if speedRaw, err := ioutil.ReadFile(filepath.Join("/sys/class/net", pfName, "speed")); err == nil {
+		if parsed, err := strconv.Atoi(strings.TrimSpace(string(speedRaw))); err == nil {
+			speed = parsed
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, sriovStatSubsystem, "pf_speed_mbps"),
+			"Link speed of the PF netdev in Mb/s, or -1 if it cannot be determined.", labels, nil),
+		prometheus.GaugeValue, float64(speed), pfName,
+	)
+
+	operstate := readSysfsString(filepath.Join("/sys/class/net", pfName, "operstate"), "unknown")
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, sriovStatSubsystem, "pf_up"),
+			"Whether the PF netdev operstate is up (1) or not (0).", labels, nil),
+		prometheus.GaugeValue, boolToFloat(operstate == "up"), pfName,
+	)
+
+	infoLabels := append(labels, "driver", "driverVersion", "pciAddress", "numaNode")
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(prometheus.BuildFQName(namespace, sriovStatSubsystem, "pf_info"),
+			"Constant metric with value 1 labeled with PF driver and topology information. Firmware "+
+				"version isn't included: it isn't exposed via sysfs and reading it needs an ethtool ioctl.",
+			infoLabels, nil),
+		prometheus.GaugeValue, 1,
+		pfName, pfDriverName(pciAddr), pfDriverVersion(pciAddr),
+		pciAddr, readSysfsString(filepath.Join(sysBusPci, pciAddr, "numa_node"), "-1"),
+	)
 }
 
-//ReadStats takes in the name of a PF and the VF Id and returns a stats object.
-func (r i40eReader) ReadStats(pfName string, vfID string ) sriovStats {
+//pfDriverName returns the kernel driver module bound to the PF's PCI device.
+func pfDriverName(pciAddr string) string {
+	driverInfo, err := os.Readlink(filepath.Join(sysBusPci, pciAddr, driverFile))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(driverInfo)
+}
+
+//pfDriverVersion returns the loaded driver module's version, when the module exposes one.
+func pfDriverVersion(pciAddr string) string {
+	driver := pfDriverName(pciAddr)
+	if driver == "" {
+		return ""
+	}
+	return readSysfsString(filepath.Join("/sys/module", driver, "version"), "")
+}
+
+//updateVFTopology emits a constant-value metric describing a VF's NUMA and IOMMU placement,
+//so scheduler-aware alerting can catch VFs landing in the wrong IOMMU group or NUMA node.
+func (c *sriovNetCollector) updateVFTopology(ch chan<- prometheus.Metric, pfPciAddr string, pfName string, vfID string, vfAddress string) {
+	vfDir := filepath.Join(sysBusPci, vfAddress)
+	numaNode := readSysfsString(filepath.Join(vfDir, "numa_node"), "-1")
+	iommuGroup := iommuGroupForPCIDevice(vfAddress)
+	driver := vfDriverName(vfAddress)
+	bound := driver != ""
+
+	labels := []string{"pfName", "vf", "vfAddress", "numaNode", "iommuGroup", "driver", "bound", "pfPci"}
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, sriovStatSubsystem, "vf_topology_info"),
+			"Constant metric with value 1 labeled with the NUMA node, IOMMU group and PCI topology of a VF.",
+			labels, nil,
+		),
+		prometheus.GaugeValue, 1,
+		pfName, vfID, vfAddress, numaNode, iommuGroup, driver, strconv.FormatBool(bound), pfPciAddr,
+	)
+}
+
+//iommuGroupForPCIDevice returns the basename of a PCI device's iommu_group symlink, or "" if
+//the device isn't in an IOMMU group.
+func iommuGroupForPCIDevice(pciAddr string) string {
+	linkName, err := os.Readlink(filepath.Join(sysBusPci, pciAddr, "iommu_group"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(linkName)
+}
+
+//vfDriverName returns the VF-side driver bound to a PCI device (usually vfio-pci or a VF PMD
+//like iavf/mlx5_core), or "" if no driver is bound.
+func vfDriverName(pciAddr string) string {
+	driverInfo, err := os.Readlink(filepath.Join(sysBusPci, pciAddr, driverFile))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(driverInfo)
+}
+
+//readStatDir reads every file in statroot as a single numeric value and returns them keyed by file name.
+//This is the layout shared by the sysfs-based per-VF stat directories across Intel drivers. logger
+//is used to report an unreadable statroot, since a wrong or unverified path otherwise fails silently
+//and just looks like a VF with zero counters.
+func readStatDir(statroot string, logger log.Logger) sriovStats {
 	stats := make(sriovStats, 0)
-	statroot   := fmt.Sprintf("/sys/class/net/%s/device/sriov/%s/stats/", pfName, vfID)
 	files , err := ioutil.ReadDir(statroot)
 	if err != nil {
+		level.Debug(logger).Log("msg", "could not read VF stat directory", "statroot", statroot, "err", err)
 		return stats
 	}
 	for _, f := range files {
@@ -223,4 +720,236 @@ func (r i40eReader) ReadStats(pfName string, vfID string ) sriovStats {
 		stats[f.Name()] = value
 	}
 	return stats
+}
+
+//i40eReader is able to read stats from Physical functions running the i40e driver.
+type i40eReader struct {
+}
+
+//ReadStats takes in the name of a PF and the VF Id and returns a stats object.
+func (r i40eReader) ReadStats(pfName string, vfID string, logger log.Logger) sriovStats {
+	statroot := fmt.Sprintf("/sys/class/net/%s/device/sriov/%s/stats/", pfName, vfID)
+	return readStatDir(statroot, logger)
+}
+
+//iceReader is able to read stats from Physical functions running the ice driver. This assumes ice
+//exposes the same per-VF sysfs stats layout as i40e; that assumption hasn't been confirmed against
+//real ice hardware, so a silently-empty stats map here should be treated as a signal to check the
+//debug log for an unreadable statroot rather than as "the VF truly has no traffic".
+type iceReader struct {
+}
+
+//ReadStats takes in the name of a PF and the VF Id and returns a stats object.
+func (r iceReader) ReadStats(pfName string, vfID string, logger log.Logger) sriovStats {
+	statroot := fmt.Sprintf("/sys/class/net/%s/device/sriov/%s/stats/", pfName, vfID)
+	return readStatDir(statroot, logger)
+}
+
+//igbReader is able to read stats from Physical functions running the igb driver. The exact sysfs
+//layout igb exposes for per-VF stats, if any, hasn't been confirmed against real igb hardware; this
+//path is a best-effort guess pending verification, and the debug log on an unreadable statroot is
+//the way to tell "wrong path" apart from "driver genuinely has no stats here".
+type igbReader struct {
+}
+
+//ReadStats takes in the name of a PF and the VF Id and returns a stats object.
+func (r igbReader) ReadStats(pfName string, vfID string, logger log.Logger) sriovStats {
+	statroot := fmt.Sprintf("/sys/class/net/%s/device/sriov_vfs/%s/stats/", pfName, vfID)
+	return readStatDir(statroot, logger)
+}
+
+//mlx5Reader is able to read stats from Physical functions running the mlx5_core driver. mlx5 does
+//not expose per-VF counters under /sys/class/net; this reads them from a debugfs counters
+//directory instead, keyed by the PF's PCI address. That debugfs layout hasn't been confirmed
+//against real mlx5 hardware and may not match mainline; treat an empty result as a cue to check
+//the debug log rather than as confirmation the VF has no traffic.
+type mlx5Reader struct {
+}
+
+//ReadStats takes in the name of a PF and the VF Id and returns a stats object.
+func (r mlx5Reader) ReadStats(pfName string, vfID string, logger log.Logger) sriovStats {
+	pfPciAddr, err := pciAddressForNetdev(pfName)
+	if err != nil {
+		level.Debug(logger).Log("msg", "could not resolve PCI address for PF", "pf", pfName, "err", err)
+		return make(sriovStats, 0)
+	}
+	statroot := fmt.Sprintf("/sys/kernel/debug/mlx5/%s/vfs/%s/counters/", pfPciAddr, vfID)
+	return readStatDir(statroot, logger)
+}
+
+//pciAddressForNetdev resolves the PCI address backing a given netdev name by following its
+///sys/class/net/<dev>/device symlink.
+func pciAddressForNetdev(netdev string) (string, error) {
+	devicePath := filepath.Join("/sys/class/net", netdev, "device")
+	linkName, err := os.Readlink(devicePath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(linkName), nil
+}
+
+//switchdevReader reads VF counters from their representor netdev, for PFs running in
+//switchdev eswitch mode where per-VF counters are no longer exposed under
+///sys/class/net/<pf>/device/sriov/<id>/stats/.
+type switchdevReader struct {
+}
+
+//ReadStats takes in the name of a PF and the VF Id and returns the representor's counters.
+func (r switchdevReader) ReadStats(pfName string, vfID string, logger log.Logger) sriovStats {
+	repName, ok := representorForVF(pfName, vfID)
+	if !ok {
+		level.Debug(logger).Log("msg", "could not find representor for VF", "pf", pfName, "vf", vfID)
+		return make(sriovStats, 0)
+	}
+	statroot := fmt.Sprintf("/sys/class/net/%s/statistics/", repName)
+	return readStatDir(statroot, logger)
+}
+
+//isSwitchdevMode reports whether a PF's eswitch is running in switchdev mode, by reading the
+//compat devlink mode file node_exporter has no devlink netlink support yet.
+func isSwitchdevMode(pfName string) bool {
+	mode := readSysfsString(filepath.Join("/sys/class/net", pfName, "compat/devlink/mode"), "legacy")
+	return mode == "switchdev"
+}
+
+//sysClassNet is the root of the netdev sysfs tree, overridable in tests.
+var sysClassNet = "/sys/class/net"
+
+//vfRepresentorPortNameRe matches a VF representor's phys_port_name, e.g. "pf0vf3".
+var vfRepresentorPortNameRe = regexp.MustCompile(`^pf(\d+)vf(\d+)$`)
+
+//pfPortIndex returns a PF's own eswitch port index (the "<N>" in a phys_port_name of "p<N>"),
+//used to tell apart the representors of different PFs sharing one phys_switch_id.
+func pfPortIndex(pfName string) (string, bool) {
+	portName := readSysfsString(filepath.Join(sysClassNet, pfName, "phys_port_name"), "")
+	if !strings.HasPrefix(portName, "p") {
+		return "", false
+	}
+	index := strings.TrimPrefix(portName, "p")
+	if index == "" {
+		return "", false
+	}
+	return index, true
+}
+
+//representorForVF finds the representor netdev for a VF of a switchdev PF. Representors share
+//the PF's phys_switch_id and carry a phys_port_name of the form "pf<N>vf<vfID>", where N is the
+//PF's own eswitch port index - checked because phys_switch_id is shared by every PF on the same
+//ASIC (e.g. both ports of a dual-port ConnectX card), so matching on the VF index alone can
+//attribute another PF's representor to this one. Some kernels don't expose phys_port_name on the
+//PF netdev itself (only on its representors/uplink-rep); when the PF index can't be determined
+//this falls back to matching on phys_switch_id and VF index alone, same as before dfcdeab.
+func representorForVF(pfName string, vfID string) (string, bool) {
+	pfSwitchID := readSysfsString(filepath.Join(sysClassNet, pfName, "phys_switch_id"), "")
+	if pfSwitchID == "" {
+		return "", false
+	}
+	pfIndex, havePFIndex := pfPortIndex(pfName)
+	netdevs, err := ioutil.ReadDir(sysClassNet)
+	if err != nil {
+		return "", false
+	}
+	for _, dev := range netdevs {
+		name := dev.Name()
+		switchID := readSysfsString(filepath.Join(sysClassNet, name, "phys_switch_id"), "")
+		if switchID != pfSwitchID {
+			continue
+		}
+		portName := readSysfsString(filepath.Join(sysClassNet, name, "phys_port_name"), "")
+		m := vfRepresentorPortNameRe.FindStringSubmatch(portName)
+		if m == nil {
+			continue
+		}
+		if m[2] != vfID {
+			continue
+		}
+		if havePFIndex && m[1] != pfIndex {
+			continue
+		}
+		return name, true
+	}
+	return "", false
+}
+
+//isSwitchdevReader reports whether reader reads VF counters from a switchdev representor,
+//unwrapping dpuReader so the check still holds for a switchdev PF running on a DPU.
+func isSwitchdevReader(reader sriovStatReader) bool {
+	switch r := reader.(type) {
+	case switchdevReader:
+		return true
+	case dpuReader:
+		return isSwitchdevReader(r.hostReader)
+	default:
+		return false
+	}
+}
+
+//dpuVendorDevice identifies a known DPU/smartNIC PF by PCI vendor:device ID. A nil device map
+//means any device ID from that vendor is treated as a DPU.
+var dpuVendorDevice = map[string]map[string]bool{
+	"0x15b3": {"0xa2d2": true, "0xa2d6": true}, // Mellanox BlueField-2/3
+	"0x1f47": nil,                              // Yusur K2 - any device ID
+}
+
+//isDPUPF reports whether a PF's PCI vendor/device ID matches a known DPU/smartNIC, whose "PF"
+//from the host's perspective is really a representor on the DPU's ARM side.
+func isDPUPF(pciAddr string) bool {
+	vendor := readSysfsString(filepath.Join(sysBusPci, pciAddr, "vendor"), "")
+	devices, ok := dpuVendorDevice[vendor]
+	if !ok {
+		return false
+	}
+	if devices == nil {
+		return true
+	}
+	device := readSysfsString(filepath.Join(sysBusPci, pciAddr, "device"), "")
+	return devices[device]
+}
+
+//dpuReader merges VF counters from a DPU-side agent with the host-side reader for the same PF,
+//if any. It degrades gracefully to host-only counters when the DPU endpoint is unreachable, so
+//the same exporter binary can be deployed on DPU and non-DPU hosts alike.
+type dpuReader struct {
+	hostReader sriovStatReader
+	endpoint   string
+	logger     log.Logger
+}
+
+//ReadStats takes in the name of a PF and the VF Id and returns the merged host+DPU stats object.
+func (r dpuReader) ReadStats(pfName string, vfID string, logger log.Logger) sriovStats {
+	stats := make(sriovStats, 0)
+	if r.hostReader != nil {
+		stats = r.hostReader.ReadStats(pfName, vfID, logger)
+	}
+	dpuStats, err := queryDPUStats(r.endpoint, pfName, vfID)
+	if err != nil {
+		level.Debug(r.logger).Log("msg", "could not reach DPU-side agent, reporting host-side counters only",
+			"endpoint", r.endpoint, "pf", pfName, "vf", vfID, "err", err)
+		return stats
+	}
+	for name, v := range dpuStats {
+		stats[name] = v
+	}
+	return stats
+}
+
+//queryDPUStats fetches per-VF counters from the DPU-side agent's management endpoint.
+func queryDPUStats(endpoint string, pfName string, vfID string) (sriovStats, error) {
+	if endpoint == "" {
+		return nil, errors.New("no DPU endpoint configured")
+	}
+	client := http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://%s/vf-stats?pf=%s&vf=%s", endpoint, pfName, vfID))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DPU agent at %s returned status %d", endpoint, resp.StatusCode)
+	}
+	stats := make(sriovStats, 0)
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
 }
\ No newline at end of file
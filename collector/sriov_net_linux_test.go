@@ -0,0 +1,165 @@
+//+build !nosriovnet
+
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+)
+
+func TestVfLinkStateString(t *testing.T) {
+	cases := []struct {
+		name  string
+		state uint32
+		want  string
+	}{
+		{"enable", netlink.VF_LINK_STATE_ENABLE, "enable"},
+		{"disable", netlink.VF_LINK_STATE_DISABLE, "disable"},
+		{"auto", netlink.VF_LINK_STATE_AUTO, "auto"},
+		{"unknown", 99, "auto"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := vfLinkStateString(c.state); got != c.want {
+				t.Errorf("vfLinkStateString(%d) = %q, want %q", c.state, got, c.want)
+			}
+		})
+	}
+}
+
+func TestIsDPUPF(t *testing.T) {
+	dir := t.TempDir()
+	oldSysBusPci := sysBusPci
+	sysBusPci = dir
+	defer func() { sysBusPci = oldSysBusPci }()
+
+	writePCIDev := func(addr, vendor, device string) {
+		devDir := filepath.Join(dir, addr)
+		if err := os.MkdirAll(devDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(devDir, "vendor"), []byte(vendor+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(devDir, "device"), []byte(device+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		addr   string
+		vendor string
+		device string
+		want   bool
+	}{
+		{"bluefield2", "0000:03:00.0", "0x15b3", "0xa2d2", true},
+		{"bluefield3", "0000:03:00.1", "0x15b3", "0xa2d6", true},
+		{"mellanox-non-dpu", "0000:03:00.2", "0x15b3", "0x1017", false},
+		{"yusur-any-device", "0000:04:00.0", "0x1f47", "0x1234", true},
+		{"unknown-vendor", "0000:05:00.0", "0x8086", "0x1572", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			writePCIDev(c.addr, c.vendor, c.device)
+			if got := isDPUPF(c.addr); got != c.want {
+				t.Errorf("isDPUPF(%s) = %v, want %v", c.addr, got, c.want)
+			}
+		})
+	}
+}
+
+func TestRepresentorForVF(t *testing.T) {
+	dir := t.TempDir()
+	oldSysClassNet := sysClassNet
+	sysClassNet = dir
+	defer func() { sysClassNet = oldSysClassNet }()
+
+	writeNetdev := func(name, switchID, portName string) {
+		devDir := filepath.Join(dir, name)
+		if err := os.MkdirAll(devDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if switchID != "" {
+			if err := os.WriteFile(filepath.Join(devDir, "phys_switch_id"), []byte(switchID+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if portName != "" {
+			if err := os.WriteFile(filepath.Join(devDir, "phys_port_name"), []byte(portName+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Dual-port NIC: pf0 and pf1 share one ASIC (phys_switch_id), each with its own
+	// uplink representor (p0/p1) and VF representors (pf<N>vf<M>).
+	writeNetdev("pf0", "switch0", "p0")
+	writeNetdev("pf1", "switch0", "p1")
+	writeNetdev("pf0vf0_rep", "switch0", "pf0vf0")
+	writeNetdev("pf0vf3_rep", "switch0", "pf0vf3")
+	writeNetdev("pf1vf3_rep", "switch0", "pf1vf3")
+	writeNetdev("unrelated", "switch1", "pf0vf3")
+
+	cases := []struct {
+		name     string
+		pfName   string
+		vfID     string
+		wantRep  string
+		wantOK   bool
+	}{
+		{"matches own pf and vf", "pf0", "3", "pf0vf3_rep", true},
+		{"does not match other pf with same vf id", "pf1", "0", "", false},
+		{"vf0 on pf0", "pf0", "0", "pf0vf0_rep", true},
+		{"pf1 vf3 matches its own representor", "pf1", "3", "pf1vf3_rep", true},
+		{"no representor for missing vf", "pf0", "7", "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := representorForVF(c.pfName, c.vfID)
+			if ok != c.wantOK || got != c.wantRep {
+				t.Errorf("representorForVF(%s, %s) = (%q, %v), want (%q, %v)",
+					c.pfName, c.vfID, got, ok, c.wantRep, c.wantOK)
+			}
+		})
+	}
+}
+
+//TestRepresentorForVFFallsBackWithoutPFPortName covers kernels where the PF netdev itself
+//exposes no phys_port_name (only its representors do): representorForVF must still find a
+//match by phys_switch_id and VF index alone, rather than failing closed.
+func TestRepresentorForVFFallsBackWithoutPFPortName(t *testing.T) {
+	dir := t.TempDir()
+	oldSysClassNet := sysClassNet
+	sysClassNet = dir
+	defer func() { sysClassNet = oldSysClassNet }()
+
+	writeNetdev := func(name, switchID, portName string) {
+		devDir := filepath.Join(dir, name)
+		if err := os.MkdirAll(devDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if switchID != "" {
+			if err := os.WriteFile(filepath.Join(devDir, "phys_switch_id"), []byte(switchID+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if portName != "" {
+			if err := os.WriteFile(filepath.Join(devDir, "phys_port_name"), []byte(portName+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// pf0 carries no phys_port_name, unlike the dual-port case above.
+	writeNetdev("pf0", "switch0", "")
+	writeNetdev("pf0vf3_rep", "switch0", "pf0vf3")
+
+	got, ok := representorForVF("pf0", "3")
+	if !ok || got != "pf0vf3_rep" {
+		t.Errorf("representorForVF(pf0, 3) = (%q, %v), want (%q, true)", got, ok, "pf0vf3_rep")
+	}
+}